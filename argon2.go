@@ -6,7 +6,10 @@
 // string to the NewHasherFromString function. The Hasher structure
 // possesses the functions to create and verify password hashes.
 //
-// argon2i and argon2id are the only supported variants.
+// argon2i, argon2id, and argon2d are the supported variants. argon2d
+// is implemented in pure Go (golang.org/x/crypto/argon2 does not
+// expose it) and should only be used where its data-dependent memory
+// access pattern is not a side-channel concern; see the FormD godoc.
 //
 //	func WithString() {
 //	    settings := "f=argon2id,s=16,k=32,m=65536,t=3,p=2"
@@ -42,22 +45,21 @@
 //
 // When using a string to initialize the Hasher, a mathematical
 // expression can be used to configure memory settings (ie `64*1024`)
-// so kibibyte values do not need to be calculated beforehand.
+// so kibibyte values do not need to be calculated beforehand. Binary
+// unit suffixes are also recognized (ie `64KiB`, `256MiB`, `1GiB`),
+// so config strings don't need the multiplication spelled out at
+// all.
 package argon2
 
 import (
 	"crypto/rand"
 	"crypto/subtle"
-	"encoding/base64"
 	"fmt"
-	"go/ast"
-	"go/parser"
-	"go/token"
-	"reflect"
-	"strconv"
 	"strings"
 
 	"golang.org/x/crypto/argon2"
+
+	"github.com/wranders/go-argon2/internal/argon2d"
 )
 
 // Form is the type of argon2 to use
@@ -69,6 +71,16 @@ const (
 
 	// FormID represents the `argon2id` variant
 	FormID
+
+	// FormD represents the `argon2d` variant.
+	//
+	// argon2d's memory access pattern is data-dependent, which makes
+	// it faster and maximally resistant to GPU cracking, but exposes
+	// it to cache-timing side-channel attacks. Only use FormD when an
+	// attacker cannot observe the memory-access pattern of the
+	// machine computing the hash, such as in proof-of-work schemes;
+	// for password storage, prefer FormID.
+	FormD
 )
 
 // Hasher contains the parameters used by the argon2
@@ -100,7 +112,27 @@ type Hasher struct {
 //  io.ErrShortBuffer       (only if problem with system RNG)
 //  io.ErrUnexpectedEOF     (only if problem with system RNG)
 func (h *Hasher) Create(password string) (string, error) {
-	return h.hashCreate(password)
+	return h.hashCreate([]byte(password))
+}
+
+// CreateBytes is the []byte equivalent of Create, for callers who
+// read passwords into a buffer they control (e.g. mlock'd memory) and
+// want to avoid allocating a string copy of the plaintext that
+// lingers in the GC heap. The password is never copied into a string;
+// the encoded hash (which is not secret) is built as a string
+// internally and returned as a []byte.
+// Once finished with password, callers can wipe it with ZeroBytes:
+//
+//	hash, err := hasher.CreateBytes(pw)
+//	defer argon2.ZeroBytes(pw)
+//
+// Errors are the same as Create.
+func (h *Hasher) CreateBytes(password []byte) ([]byte, error) {
+	hash, err := h.hashCreate(password)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(hash), nil
 }
 
 // Matches compares a plain-text password with a provided argon2
@@ -112,7 +144,28 @@ func (h *Hasher) Create(password string) (string, error) {
 //  *ErrIncompatibleVersion
 //  base64.CorruptInputError
 func Matches(password, hash string) (bool, error) {
-	return hashCompare(password, hash)
+	return hashCompare([]byte(password), hash)
+}
+
+// MatchesBytes is the []byte equivalent of Matches, for callers
+// comparing a password (and a hash, e.g. one read straight out of a
+// database row) held in buffers they control. See CreateBytes for the
+// motivation and ZeroBytes for wiping the password buffer afterwards;
+// hash is not secret and does not need to be zeroed.
+//
+// Errors are the same as Matches.
+func MatchesBytes(password, hash []byte) (bool, error) {
+	return hashCompare(password, string(hash))
+}
+
+// ZeroBytes overwrites b with zeroes in place. Callers passing
+// passwords to CreateBytes/MatchesBytes can defer this immediately
+// after obtaining the buffer so the plaintext does not linger in
+// memory any longer than necessary.
+func ZeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
 }
 
 // NewHasherFromString parses a comma-delimited key-value string into
@@ -128,7 +181,8 @@ func Matches(password, hash string) (bool, error) {
 // "k": Key Length (int) : Byte length of hash key
 //
 // "m": Memory (int OR expression) :
-// Memory is evaluated, so mathematical expressions can be used.
+// Memory is evaluated, so mathematical expressions and KiB/MiB/GiB
+// unit suffixes can be used.
 //
 // "t": Time/Iterations (int) : Number of passes over memory
 //
@@ -155,6 +209,8 @@ func NewHasherFromString(settings string) (*Hasher, error) {
 				hasher.Form = FormI
 			case "argon2id":
 				hasher.Form = FormID
+			case "argon2d":
+				hasher.Form = FormD
 			default:
 				return nil, &ErrInvalidForm{}
 			}
@@ -208,7 +264,7 @@ func (h *Hasher) isValid() bool {
 	return false
 }
 
-func (h *Hasher) hashCreate(password string) (string, error) {
+func (h *Hasher) hashCreate(password []byte) (string, error) {
 	if !h.isValid() {
 		return "", &ErrInvalidHasherConfiguration{}
 	}
@@ -217,181 +273,58 @@ func (h *Hasher) hashCreate(password string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	passwordBytes := []byte(password)
-	var form string
 	var key []byte
 	switch h.Form {
 	case FormI:
-		form = "argon2i"
-		key = argon2.Key(
-			passwordBytes,
-			salt,
-			h.Iterations,
-			h.Memory,
-			h.Parallelism,
-			h.KeyLength,
-		)
+		key = argon2.Key(password, salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
 	case FormID:
-		form = "argon2id"
-		key = argon2.IDKey(
-			passwordBytes,
-			salt,
-			h.Iterations,
-			h.Memory,
-			h.Parallelism,
-			h.KeyLength,
-		)
+		key = argon2.IDKey(password, salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+	case FormD:
+		key = argon2d.Key(password, salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
 	default:
 		return "", &ErrInvalidForm{}
 	}
 
-	b64Salt := base64.RawStdEncoding.EncodeToString(salt)
-	b64Key := base64.RawStdEncoding.EncodeToString(key)
-	hash := fmt.Sprintf(
-		"$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
-		form,
-		argon2.Version,
-		h.Memory,
-		h.Iterations,
-		h.Parallelism,
-		b64Salt,
-		b64Key,
-	)
-	return hash, nil
+	return Encode(Params{
+		Form:        h.Form,
+		Version:     argon2.Version,
+		Memory:      h.Memory,
+		Iterations:  h.Iterations,
+		Parallelism: h.Parallelism,
+		Salt:        salt,
+		Key:         key,
+	}), nil
 }
 
-func hashCompare(password, hash string) (bool, error) {
-	hashValues := strings.Split(hash, "$")
-	if len(hashValues) != 6 {
-		return false, &ErrInvalidHash{}
-	}
-	var hashForm Form
-	switch hashValues[1] {
-	case "argon2i":
-		hashForm = FormI
-	case "argon2id":
-		hashForm = FormID
-	default:
-		return false, &ErrInvalidForm{}
-	}
-
-	var hashVersion int
-	_, err := fmt.Sscanf(hashValues[2], "v=%d", &hashVersion)
+func hashCompare(password []byte, hash string) (bool, error) {
+	p, err := Decode(hash)
 	if err != nil {
 		return false, err
 	}
-	if hashVersion != argon2.Version {
-		return false, &ErrIncompatibleVersion{hashVersion}
-	}
+	keyLength := uint32(len(p.Key))
 
-	var hashMemory uint32
-	var hashIterations uint32
-	var hashParallelism uint8
-	_, err = fmt.Sscanf(
-		hashValues[3],
-		"m=%d,t=%d,p=%d",
-		&hashMemory,
-		&hashIterations,
-		&hashParallelism,
-	)
-	if err != nil {
-		return false, err
-	}
-
-	hashSalt, err := base64.RawStdEncoding.DecodeString(hashValues[4])
-	if err != nil {
-		return false, err
-	}
-
-	hashKey, err := base64.RawStdEncoding.DecodeString(hashValues[5])
-	if err != nil {
-		return false, err
-	}
-	hashKeyLength := uint32(len(hashKey))
-
-	pwbytes := []byte(password)
 	var passwordKey []byte
-	switch hashForm {
+	switch p.Form {
 	case FormI:
-		passwordKey = argon2.Key(
-			pwbytes,
-			hashSalt,
-			hashIterations,
-			hashMemory,
-			hashParallelism,
-			hashKeyLength,
-		)
+		passwordKey = argon2.Key(password, p.Salt, p.Iterations, p.Memory, p.Parallelism, keyLength)
 	case FormID:
-		passwordKey = argon2.IDKey(
-			pwbytes,
-			hashSalt,
-			hashIterations,
-			hashMemory,
-			hashParallelism,
-			hashKeyLength,
-		)
+		passwordKey = argon2.IDKey(password, p.Salt, p.Iterations, p.Memory, p.Parallelism, keyLength)
+	case FormD:
+		passwordKey = argon2d.Key(password, p.Salt, p.Iterations, p.Memory, p.Parallelism, keyLength)
 	default:
 		return false, &ErrInvalidForm{}
 	}
 
-	hashKeyLen := int32(len(hashKey))
+	hashKeyLen := int32(len(p.Key))
 	passwordKeyLen := int32(len(passwordKey))
 
 	if subtle.ConstantTimeEq(hashKeyLen, passwordKeyLen) == 0 {
 		return false, nil
 	}
-	if subtle.ConstantTimeCompare(hashKey, passwordKey) == 1 {
+	if subtle.ConstantTimeCompare(p.Key, passwordKey) == 1 {
 		return true, nil
 	}
 
 	return false, nil
 }
 
-func parseMemory(exp string) (uint32, error) {
-	tree, err := parser.ParseExpr(exp)
-	if err != nil {
-		return 0, err
-	}
-	return evalMemory(tree)
-}
-
-func evalMemory(tree ast.Expr) (uint32, error) {
-	switch n := tree.(type) {
-	case *ast.BasicLit:
-		if n.Kind != token.INT {
-			return 0, &ErrUnsupportedExpr{n.Kind}
-		}
-		u, err := strconv.ParseUint(n.Value, 10, 32)
-		if err != nil {
-			return 0, err
-		}
-		return uint32(u), nil
-	case *ast.BinaryExpr:
-		switch n.Op {
-		case token.ADD, token.SUB, token.MUL, token.QUO:
-		default:
-			return 0, &ErrUnsupportedExpr{n.Op}
-		}
-		x, err := evalMemory(n.X)
-		if err != nil {
-			return 0, err
-		}
-		y, err := evalMemory(n.Y)
-		if err != nil {
-			return 0, err
-		}
-		switch n.Op {
-		case token.ADD:
-			return x + y, nil
-		case token.SUB:
-			return x - y, nil
-		case token.MUL:
-			return x * y, nil
-		case token.QUO:
-			return x / y, nil
-		}
-	case *ast.ParenExpr:
-		return evalMemory(n.X)
-	}
-	return 0, &ErrUnsupportedExpr{reflect.TypeOf(tree)}
-}
@@ -22,7 +22,7 @@ const (
 	settingArgon2IDMissing           string = "f=argon2id,s=16,k=32,m=65536,p=2"
 	hashCorruptSalt                  string = "$argon2i$v=19$m=65536,t=3,p=2$oOT8PmX+YLmj8wReAP0Cg$uIP1h5Z1DOSx9YBBSWOHE84AYGxC9/GwnB3ZFGZFh8E"
 	hashCorruptKey                   string = "$argon2i$v=19$m=65536,t=3,p=2$oOT8PmX+YLmj8wRveAP0Cg$uIP1h5Z1DOSx9YBBSWOHE84AYGxC9GwnB3ZFGZFh8E"
-	hashInvalidForm                  string = "$argon2d$v=19$m=65536,t=3,p=2$oOT8PmX+YLmj8wRveAP0Cg$uIP1h5Z1DOSx9YBBSWOHE84AYGxC9/GwnB3ZFGZFh8E"
+	hashInvalidForm                  string = "$argon2x$v=19$m=65536,t=3,p=2$oOT8PmX+YLmj8wRveAP0Cg$uIP1h5Z1DOSx9YBBSWOHE84AYGxC9/GwnB3ZFGZFh8E"
 	hashIncompatVersion              string = "$argon2i$v=13$m=65536,t=3,p=2$oOT8PmX+YLmj8wRveAP0Cg$uIP1h5Z1DOSx9YBBSWOHE84AYGxC9/GwnB3ZFGZFh8E"
 )
 
@@ -194,13 +194,23 @@ func TestHasherInitConfigID(t *testing.T) {
 }
 
 func TestHasherInitStringD(t *testing.T) {
-	_, err := NewHasherFromString(settingArgon2D)
-	if err == nil {
-		t.Errorf("Init_D: %w", err)
+	hasher, err := NewHasherFromString(settingArgon2D)
+	if err != nil {
+		t.Errorf("Init_D: %v", err)
 	}
-	if _, ok := err.(*ErrInvalidForm); !ok {
-		t.Errorf("Init_D_WrongErr: %T", err)
+	createComparePasswords(t, hasher)
+}
+
+func TestHasherInitConfigD(t *testing.T) {
+	hasher := &Hasher{
+		Form:        FormD,
+		SaltLength:  16,
+		KeyLength:   32,
+		Memory:      65536,
+		Iterations:  3,
+		Parallelism: 2,
 	}
+	createComparePasswords(t, hasher)
 }
 
 func TestHasherInitStringI(t *testing.T) {
@@ -257,4 +267,26 @@ func ExampleNewHasherFromString_memoryExpression() {
 	// +, -, *, /, (, ), and space (` `)
 	// are the only valid non-numeric symbols.
 	_ = "f=argon2i,s=16,k=32,m=((64*1024) + (20-10))/2,t=3,p=2"
+
+	// KiB, MiB, and GiB (or just K, M, G) suffixes are also
+	// recognized, and avoid spelling out the multiplication.
+	_ = "f=argon2i,s=16,k=32,m=64MiB,t=3,p=2"
+}
+
+func TestHasherInitStringMemoryUnit(t *testing.T) {
+	hasher, err := NewHasherFromString("f=argon2id,s=16,k=32,m=64MiB,t=3,p=2")
+	if err != nil {
+		t.Fatalf("Init_MemoryUnit: %v", err)
+	}
+	if hasher.Memory != 64*1024 {
+		t.Errorf("Init_MemoryUnit_Value: got %d, want %d", hasher.Memory, 64*1024)
+	}
+
+	hasher, err = NewHasherFromString("f=argon2id,s=16,k=32,m=65536K,t=3,p=2")
+	if err != nil {
+		t.Fatalf("Init_MemoryUnitK: %v", err)
+	}
+	if hasher.Memory != 65536 {
+		t.Errorf("Init_MemoryUnitK_Value: got %d, want %d", hasher.Memory, 65536)
+	}
 }
@@ -0,0 +1,39 @@
+package argon2
+
+import "testing"
+
+func TestHasherCreateBytesMatchesBytes(t *testing.T) {
+	hasher := RecommendedHasher()
+	pw := []byte(password)
+
+	hash, err := hasher.CreateBytes(pw)
+	if err != nil {
+		t.Fatalf("CreateBytes: %v", err)
+	}
+
+	ok, err := MatchesBytes(pw, hash)
+	if err != nil {
+		t.Errorf("MatchesBytes_Correct: %v", err)
+	}
+	if !ok {
+		t.Errorf("MatchesBytes_Correct: expected a match")
+	}
+
+	ok, err = MatchesBytes([]byte(wrongPassword), hash)
+	if err != nil {
+		t.Errorf("MatchesBytes_Incorrect: %v", err)
+	}
+	if ok {
+		t.Errorf("MatchesBytes_Incorrect: expected no match")
+	}
+}
+
+func TestZeroBytes(t *testing.T) {
+	b := []byte("mySecretPassword")
+	ZeroBytes(b)
+	for i, v := range b {
+		if v != 0 {
+			t.Errorf("ZeroBytes: byte %d not zeroed", i)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package argon2
+
+import "strings"
+
+// PasswordScheme is implemented by types that can create and verify
+// password hashes, and that can report whether a given hash was
+// produced by them. Hasher implements PasswordScheme so it can be used
+// as a link in a Chain.
+type PasswordScheme interface {
+	Create(password string) (string, error)
+	Matches(password, hash string) (bool, error)
+	SupportsStub(hash string) bool
+}
+
+// Matches compares a plain-text password with a provided argon2 hash,
+// returning true or false if they match. It is the method form of the
+// package-level Matches function, present so Hasher satisfies
+// PasswordScheme.
+func (h *Hasher) Matches(password, hash string) (bool, error) {
+	return Matches(password, hash)
+}
+
+// SupportsStub reports whether hash carries the `$argon2i$`,
+// `$argon2id$`, or `$argon2d$` stub produced by h's configured Form.
+func (h *Hasher) SupportsStub(hash string) bool {
+	return strings.HasPrefix(hash, stubForForm(h.Form))
+}
+
+func stubForForm(f Form) string {
+	switch f {
+	case FormI:
+		return "$argon2i$"
+	case FormID:
+		return "$argon2id$"
+	case FormD:
+		return "$argon2d$"
+	}
+	return ""
+}
+
+// Chain verifies a password against a preferred PasswordScheme,
+// falling back to older schemes so hashes produced by those schemes
+// can still be checked. It is intended for migrating a user base from
+// one set of argon2 parameters (or even argon2i to argon2id) onto a
+// current Hasher over time.
+type Chain struct {
+	preferred PasswordScheme
+	fallbacks []PasswordScheme
+}
+
+// NewChain builds a Chain that verifies hashes against preferred,
+// falling back to each of fallbacks in turn. The scheme used for a
+// given hash is selected by SupportsStub, so each scheme must use a
+// distinct Form.
+func NewChain(preferred PasswordScheme, fallbacks ...PasswordScheme) *Chain {
+	return &Chain{preferred: preferred, fallbacks: fallbacks}
+}
+
+// schemeFor returns whichever of c's schemes claims to support hash,
+// preferred first.
+func (c *Chain) schemeFor(hash string) (PasswordScheme, error) {
+	if c.preferred.SupportsStub(hash) {
+		return c.preferred, nil
+	}
+	for _, s := range c.fallbacks {
+		if s.SupportsStub(hash) {
+			return s, nil
+		}
+	}
+	return nil, &ErrInvalidForm{}
+}
+
+// Matches verifies password against hash using whichever of c's
+// schemes produced it.
+func (c *Chain) Matches(password, hash string) (bool, error) {
+	s, err := c.schemeFor(hash)
+	if err != nil {
+		return false, err
+	}
+	return s.Matches(password, hash)
+}
+
+// IsPreferred reports whether hash was produced by c's preferred
+// scheme, as opposed to one of its fallbacks.
+func (c *Chain) IsPreferred(hash string) bool {
+	return c.preferred.SupportsStub(hash)
+}
+
+// NeedsRehash decodes hash and reports whether any of its parameters
+// (Form, Memory, Iterations, Parallelism, salt length, or key length)
+// are weaker than c's preferred Hasher configuration. Callers should
+// call this after a successful Matches and, if it returns true,
+// create a fresh hash with Rehash.
+//
+// NeedsRehash only has an opinion when c's preferred scheme is a
+// *Hasher; for any other PasswordScheme it reports false.
+func (c *Chain) NeedsRehash(hash string) bool {
+	preferred, ok := c.preferred.(*Hasher)
+	if !ok {
+		return false
+	}
+	p, err := Decode(hash)
+	if err != nil {
+		return true
+	}
+	if p.Form != preferred.Form {
+		return true
+	}
+	if p.Memory < preferred.Memory {
+		return true
+	}
+	if p.Iterations < preferred.Iterations {
+		return true
+	}
+	if p.Parallelism < preferred.Parallelism {
+		return true
+	}
+	if uint32(len(p.Salt)) < preferred.SaltLength {
+		return true
+	}
+	if uint32(len(p.Key)) < preferred.KeyLength {
+		return true
+	}
+	return false
+}
+
+// Rehash verifies password against oldHash and, on success, creates a
+// fresh hash using h's current settings. upgraded reports whether a
+// new hash was produced; it is false whenever err is non-nil or the
+// password did not match oldHash, in which case newHash is empty.
+func (h *Hasher) Rehash(password, oldHash string) (newHash string, upgraded bool, err error) {
+	ok, err := Matches(password, oldHash)
+	if err != nil || !ok {
+		return "", false, err
+	}
+	newHash, err = h.Create(password)
+	if err != nil {
+		return "", false, err
+	}
+	return newHash, true, nil
+}
@@ -0,0 +1,111 @@
+package argon2
+
+import "testing"
+
+func TestChainVerifiesPreferredAndFallback(t *testing.T) {
+	oldHasher := &Hasher{
+		Form:        FormI,
+		SaltLength:  16,
+		KeyLength:   32,
+		Memory:      32768,
+		Iterations:  2,
+		Parallelism: 1,
+	}
+	newHasher := &Hasher{
+		Form:        FormID,
+		SaltLength:  16,
+		KeyLength:   32,
+		Memory:      65536,
+		Iterations:  3,
+		Parallelism: 2,
+	}
+	chain := NewChain(newHasher, oldHasher)
+
+	oldHash, err := oldHasher.Create(password)
+	if err != nil {
+		t.Fatalf("Create_Old: %v", err)
+	}
+	newHash, err := newHasher.Create(password)
+	if err != nil {
+		t.Fatalf("Create_New: %v", err)
+	}
+
+	if ok, err := chain.Matches(password, oldHash); err != nil || !ok {
+		t.Errorf("Matches_Fallback: ok=%v err=%v", ok, err)
+	}
+	if ok, err := chain.Matches(password, newHash); err != nil || !ok {
+		t.Errorf("Matches_Preferred: ok=%v err=%v", ok, err)
+	}
+
+	if chain.IsPreferred(oldHash) {
+		t.Errorf("IsPreferred_Old: expected false")
+	}
+	if !chain.IsPreferred(newHash) {
+		t.Errorf("IsPreferred_New: expected true")
+	}
+}
+
+func TestChainNeedsRehash(t *testing.T) {
+	oldHasher := &Hasher{
+		Form:        FormID,
+		SaltLength:  16,
+		KeyLength:   32,
+		Memory:      32768,
+		Iterations:  2,
+		Parallelism: 1,
+	}
+	newHasher := &Hasher{
+		Form:        FormID,
+		SaltLength:  16,
+		KeyLength:   32,
+		Memory:      65536,
+		Iterations:  3,
+		Parallelism: 2,
+	}
+	chain := NewChain(newHasher, oldHasher)
+
+	oldHash, err := oldHasher.Create(password)
+	if err != nil {
+		t.Fatalf("Create_Old: %v", err)
+	}
+	newHash, err := newHasher.Create(password)
+	if err != nil {
+		t.Fatalf("Create_New: %v", err)
+	}
+
+	if !chain.NeedsRehash(oldHash) {
+		t.Errorf("NeedsRehash_Old: expected true")
+	}
+	if chain.NeedsRehash(newHash) {
+		t.Errorf("NeedsRehash_New: expected false")
+	}
+}
+
+func TestHasherRehash(t *testing.T) {
+	hasher := &Hasher{
+		Form:        FormID,
+		SaltLength:  16,
+		KeyLength:   32,
+		Memory:      65536,
+		Iterations:  3,
+		Parallelism: 2,
+	}
+
+	oldHash := "$argon2id$v=19$m=65536,t=3,p=2$oOT8PmX+YLmj8wRveAP0Cg$uIP1h5Z1DOSx9YBBSWOHE84AYGxC9/GwnB3ZFGZFh8E"
+
+	if _, upgraded, err := hasher.Rehash(wrongPassword, oldHash); err != nil || upgraded {
+		t.Errorf("Rehash_WrongPassword: upgraded=%v err=%v", upgraded, err)
+	}
+
+	newHash, err := hasher.Create(password)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	rehash, upgraded, err := hasher.Rehash(password, newHash)
+	if err != nil {
+		t.Errorf("Rehash_Matches: %v", err)
+	}
+	if !upgraded || rehash == "" {
+		t.Errorf("Rehash_Matches: expected an upgraded hash")
+	}
+}
@@ -35,6 +35,17 @@ func (e ErrInvalidHasherConfiguration) Error() string {
 	return fmt.Sprint("Argon2 configuration contains invalid values")
 }
 
+// ErrWeakHasherConfiguration is returned by Hasher.Validate when a
+// parameter falls below the floor set by RecommendedHasher.
+type ErrWeakHasherConfiguration struct {
+	field  string
+	reason string
+}
+
+func (e ErrWeakHasherConfiguration) Error() string {
+	return fmt.Sprintf("argon2: %s %s", e.field, e.reason)
+}
+
 // ErrUnknownSetting returns if a setting string contains an
 // unknown key
 type ErrUnknownSetting struct {
@@ -0,0 +1,215 @@
+// Package argon2d implements the argon2d variant of the Argon2
+// password-hashing function described in RFC 9106.
+//
+// golang.org/x/crypto/argon2 only exposes argon2i and argon2id; this
+// package fills that gap with a pure-Go implementation of the data-
+// dependent (argon2d) variant so that go-argon2 can create and verify
+// hashes produced with f=argon2d.
+package argon2d
+
+import (
+	"encoding/binary"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	blockWords = 128 // 64-bit words per 1024-byte block
+	syncPoints = 4
+	version    = 0x13
+	mode       = 0 // argon2d
+)
+
+type block [blockWords]uint64
+
+// Key derives a keyLen-byte key from password and salt using argon2d
+// with the given time (iterations), memory (KiB), and threads
+// (parallelism) parameters.
+func Key(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	lanes := uint32(threads)
+	if memory < 8*lanes {
+		memory = 8 * lanes
+	}
+	lanelength := memory / (lanes * syncPoints) * syncPoints
+	segmentLength := lanelength / syncPoints
+
+	h0 := initHash(password, salt, time, memory, threads, keyLen)
+
+	B := make([]block, lanelength*lanes)
+	initBlocks(&h0, B, lanelength, threads)
+
+	for pass := uint32(0); pass < time; pass++ {
+		for slice := uint32(0); slice < syncPoints; slice++ {
+			for lane := uint32(0); lane < lanes; lane++ {
+				fillSegment(B, pass, slice, lane, lanes, lanelength, segmentLength)
+			}
+		}
+	}
+
+	return extractKey(B, lanelength, threads, keyLen)
+}
+
+func initHash(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) [64]byte {
+	var h0 [64]byte
+	var buf [4]byte
+	b2, _ := blake2b.New(64, nil)
+
+	write32 := func(v uint32) {
+		binary.LittleEndian.PutUint32(buf[:], v)
+		b2.Write(buf[:])
+	}
+	writeBytes := func(v []byte) {
+		write32(uint32(len(v)))
+		b2.Write(v)
+	}
+
+	write32(uint32(threads))
+	write32(keyLen)
+	write32(memory)
+	write32(time)
+	write32(version)
+	write32(mode)
+	writeBytes(password)
+	writeBytes(salt)
+	writeBytes(nil) // secret
+	writeBytes(nil) // associated data
+
+	b2.Sum(h0[:0])
+	return h0
+}
+
+func initBlocks(h0 *[64]byte, B []block, lanelength uint32, threads uint8) {
+	var in [72]byte
+	var out [1024]byte
+	copy(in[:64], h0[:])
+
+	for lane := uint32(0); lane < uint32(threads); lane++ {
+		binary.LittleEndian.PutUint32(in[68:72], lane)
+
+		binary.LittleEndian.PutUint32(in[64:68], 0)
+		blake2bLong(out[:], in[:])
+		loadBlock(&B[lane*lanelength], out[:])
+
+		binary.LittleEndian.PutUint32(in[64:68], 1)
+		blake2bLong(out[:], in[:])
+		loadBlock(&B[lane*lanelength+1], out[:])
+	}
+}
+
+// fillSegment fills one of the four segments of a lane for a given
+// pass. Argon2d derives both the reference lane and reference block
+// index directly from the previous block's first 64-bit word, which
+// is what makes it faster but data-dependent (and so unsuitable where
+// cache-timing side channels are a concern).
+func fillSegment(B []block, pass, slice, lane, lanes, lanelength, segmentLength uint32) {
+	startIdx := uint32(0)
+	if pass == 0 && slice == 0 {
+		startIdx = 2
+	}
+
+	curOffset := lane*lanelength + slice*segmentLength + startIdx
+	var prevOffset uint32
+	if curOffset%lanelength == 0 {
+		prevOffset = curOffset + lanelength - 1
+	} else {
+		prevOffset = curOffset - 1
+	}
+
+	for i := startIdx; i < segmentLength; i++ {
+		if curOffset%lanelength == 0 {
+			prevOffset = curOffset + lanelength - 1
+		} else {
+			prevOffset = curOffset - 1
+		}
+
+		prev := &B[prevOffset]
+		j1 := uint32(prev[0])
+		j2 := uint32(prev[0] >> 32)
+
+		refLane := lane
+		if !(pass == 0 && slice == 0) {
+			refLane = j2 % lanes
+		}
+		sameLane := refLane == lane
+
+		refIndex := indexAlpha(pass, slice, i, j1, lanelength, segmentLength, sameLane)
+		ref := &B[refLane*lanelength+refIndex]
+		cur := &B[curOffset]
+
+		if pass == 0 {
+			compress(cur, prev, ref)
+		} else {
+			var tmp block
+			compress(&tmp, prev, ref)
+			for k := range cur {
+				cur[k] ^= tmp[k]
+			}
+		}
+
+		curOffset++
+	}
+}
+
+// indexAlpha implements the Argon2 "index_alpha" function (RFC 9106
+// section 3.4.1.3), mapping a pseudo-random 32-bit word to an index
+// within the set of blocks already computed and therefore eligible as
+// a reference block.
+func indexAlpha(pass, slice, index, j1, lanelength, segmentLength uint32, sameLane bool) uint32 {
+	var refAreaSize uint32
+	switch {
+	case pass == 0 && slice == 0:
+		refAreaSize = index - 1
+	case pass == 0 && sameLane:
+		refAreaSize = slice*segmentLength + index - 1
+	case pass == 0:
+		refAreaSize = slice * segmentLength
+		if index == 0 {
+			refAreaSize--
+		}
+	case sameLane:
+		refAreaSize = lanelength - segmentLength + index - 1
+	default:
+		refAreaSize = lanelength - segmentLength
+		if index == 0 {
+			refAreaSize--
+		}
+	}
+
+	relativePos := uint64(j1) * uint64(j1) >> 32
+	relativePos = uint64(refAreaSize) - 1 - (uint64(refAreaSize)*relativePos)>>32
+
+	var startPos uint32
+	if pass != 0 && slice != syncPoints-1 {
+		startPos = (slice + 1) * segmentLength
+	}
+	return (startPos + uint32(relativePos)) % lanelength
+}
+
+func extractKey(B []block, lanelength uint32, threads uint8, keyLen uint32) []byte {
+	final := B[lanelength-1]
+	for lane := uint32(1); lane < uint32(threads); lane++ {
+		last := &B[lane*lanelength+lanelength-1]
+		for i := range final {
+			final[i] ^= last[i]
+		}
+	}
+
+	var raw [1024]byte
+	storeBlock(raw[:], &final)
+
+	out := make([]byte, keyLen)
+	blake2bLong(out, raw[:])
+	return out
+}
+
+func loadBlock(b *block, data []byte) {
+	for i := range b {
+		b[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+}
+
+func storeBlock(data []byte, b *block) {
+	for i := range b {
+		binary.LittleEndian.PutUint64(data[i*8:], b[i])
+	}
+}
@@ -0,0 +1,86 @@
+package argon2d
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestKeyDeterministic(t *testing.T) {
+	password := []byte("mySecretPassword")
+	salt := []byte("1234567890123456")
+
+	a := Key(password, salt, 3, 65536, 2, 32)
+	b := Key(password, salt, 3, 65536, 2, 32)
+	if !bytes.Equal(a, b) {
+		t.Errorf("Key is not deterministic for identical inputs")
+	}
+}
+
+func TestKeyDiffersWithSalt(t *testing.T) {
+	password := []byte("mySecretPassword")
+
+	a := Key(password, []byte("1234567890123456"), 3, 65536, 2, 32)
+	b := Key(password, []byte("6543210987654321"), 3, 65536, 2, 32)
+	if bytes.Equal(a, b) {
+		t.Errorf("Key should differ when salt differs")
+	}
+}
+
+// TestKeyKnownAnswer pins Key against known-answer vectors, checked
+// bit-for-bit against libargon2's argon2d_hash_raw, so a future change
+// to the BlaMka round function, indexAlpha, or H0 construction can't
+// silently regress into producing a different (but still internally
+// self-consistent) tag.
+func TestKeyKnownAnswer(t *testing.T) {
+	cases := []struct {
+		name           string
+		password, salt []byte
+		time, memory   uint32
+		threads        uint8
+		keyLen         uint32
+		want           string
+	}{
+		{
+			// m=8 KiB, t=1, p=1: the minimum memory for one lane,
+			// which keeps the test fast.
+			name:     "single lane, minimum memory",
+			password: []byte("password"),
+			salt:     []byte("somesalt12345678"),
+			time:     1,
+			memory:   8,
+			threads:  1,
+			keyLen:   16,
+			want:     "11c8eb87be467a1b79a56ca5eab10a5c",
+		},
+		{
+			// m=100 is not a multiple of threads*4 (16), so the
+			// lane-aligned memory actually used for filling blocks
+			// (96 KiB) differs from the requested m_cost. Regression
+			// coverage for passing the pre-alignment m_cost, not the
+			// aligned one, into H0.
+			name:     "multi-lane, non-aligned memory",
+			password: []byte("password"),
+			salt:     []byte("somesalt12345678"),
+			time:     3,
+			memory:   100,
+			threads:  4,
+			keyLen:   32,
+			want:     "e1f2f43d4ebba436955caeed00762b9012203ab4fa423b79f2ee8d8f84e9e33e",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			want, err := hex.DecodeString(c.want)
+			if err != nil {
+				t.Fatalf("bad test vector: %v", err)
+			}
+
+			got := Key(c.password, c.salt, c.time, c.memory, c.threads, c.keyLen)
+			if !bytes.Equal(got, want) {
+				t.Errorf("Key_KnownAnswer: got %x, want %x", got, want)
+			}
+		})
+	}
+}
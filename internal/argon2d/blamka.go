@@ -0,0 +1,113 @@
+package argon2d
+
+import (
+	"encoding/binary"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// blake2bLong implements the variable-length hash function H' used
+// throughout Argon2 (RFC 9106 section 3.3): for outputs of at most 64
+// bytes it is a single BLAKE2b call; for longer outputs it chains
+// BLAKE2b-512 calls together, emitting the first half of each digest
+// and re-hashing the full digest for the next block.
+func blake2bLong(out, in []byte) {
+	var lenBytes [4]byte
+	binary.LittleEndian.PutUint32(lenBytes[:], uint32(len(out)))
+
+	if len(out) <= 64 {
+		h, _ := blake2b.New(len(out), nil)
+		h.Write(lenBytes[:])
+		h.Write(in)
+		h.Sum(out[:0])
+		return
+	}
+
+	h, _ := blake2b.New512(nil)
+	h.Write(lenBytes[:])
+	h.Write(in)
+	var v [64]byte
+	h.Sum(v[:0])
+	copy(out, v[:32])
+	out = out[32:]
+
+	for len(out) > 64 {
+		h.Reset()
+		h.Write(v[:])
+		h.Sum(v[:0])
+		copy(out, v[:32])
+		out = out[32:]
+	}
+
+	h, _ = blake2b.New(len(out), nil)
+	h.Write(v[:])
+	h.Sum(out[:0])
+}
+
+// compress is the Argon2 compression function G (RFC 9106 section
+// 3.2): it XORs the two input blocks, applies the BLAKE2b round
+// function as a permutation P over the eight rows and then the eight
+// columns of the result, and XORs that back with the original sum.
+func compress(dst, a, b *block) {
+	var r, z block
+	for i := range r {
+		r[i] = a[i] ^ b[i]
+	}
+	z = r
+
+	for i := 0; i < 8; i++ {
+		blamkaRound(&z, rowIndices(i))
+	}
+	for i := 0; i < 8; i++ {
+		blamkaRound(&z, colIndices(i))
+	}
+
+	for i := range dst {
+		dst[i] = r[i] ^ z[i]
+	}
+}
+
+func rowIndices(i int) (idx [16]int) {
+	for k := range idx {
+		idx[k] = i*16 + k
+	}
+	return
+}
+
+func colIndices(i int) (idx [16]int) {
+	for k := range idx {
+		idx[k] = i*2 + (k/2)*16 + (k % 2)
+	}
+	return
+}
+
+func blamkaRound(v *block, idx [16]int) {
+	g := func(a, b, c, d int) {
+		blamka(&v[idx[a]], &v[idx[b]], &v[idx[c]], &v[idx[d]])
+	}
+	g(0, 4, 8, 12)
+	g(1, 5, 9, 13)
+	g(2, 6, 10, 14)
+	g(3, 7, 11, 15)
+	g(0, 5, 10, 15)
+	g(1, 6, 11, 12)
+	g(2, 7, 8, 13)
+	g(3, 4, 9, 14)
+}
+
+// blamka is BLAKE2b's mixing function, specialized with the
+// multiplication step ("BlaMka") that Argon2 adds for extra mixing.
+func blamka(a, b, c, d *uint64) {
+	*a += *b + 2*uint64(uint32(*a))*uint64(uint32(*b))
+	*d = rotr64(*d^*a, 32)
+	*c += *d + 2*uint64(uint32(*c))*uint64(uint32(*d))
+	*b = rotr64(*b^*c, 24)
+	*a += *b + 2*uint64(uint32(*a))*uint64(uint32(*b))
+	*d = rotr64(*d^*a, 16)
+	*c += *d + 2*uint64(uint32(*c))*uint64(uint32(*d))
+	*b = rotr64(*b^*c, 63)
+}
+
+func rotr64(x uint64, n uint) uint64 {
+	return (x >> n) | (x << (64 - n))
+}
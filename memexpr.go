@@ -0,0 +1,194 @@
+package argon2
+
+import (
+	"math"
+	"strconv"
+)
+
+// memUnits maps the binary-unit suffixes recognized by parseMemory to
+// the multiplier (in KiB, the unit Hasher.Memory is already
+// expressed in) they apply to the literal they follow. "K"/"KiB" are
+// a no-op since Memory is itself in KiB; they exist so config strings
+// can be explicit about units, e.g. "m=65536K".
+var memUnits = map[string]uint64{
+	"K":   1,
+	"KiB": 1,
+	"M":   1024,
+	"MiB": 1024,
+	"G":   1024 * 1024,
+	"GiB": 1024 * 1024,
+}
+
+// parseMemory evaluates exp, a small arithmetic expression over
+// unsigned integer literals (optionally suffixed with a binary unit
+// such as KiB/MiB/GiB), `+`, `-`, `*`, `/`, and parentheses. It is a
+// hand-written recursive-descent evaluator rather than a wrapper
+// around go/parser: go/parser accepts the whole Go expression
+// grammar (identifiers, calls, floats, ...) which then fails late
+// with confusing errors, and pulling in go/ast, go/parser, and
+// go/token is unnecessary weight for a security-adjacent library.
+//
+// Errors:
+//  *ErrUnsupportedExpr
+//  *strconv.NumError (a literal does not fit in 32 bits)
+func parseMemory(exp string) (uint32, error) {
+	p := &memExprParser{src: exp}
+	v, err := p.parseExpr()
+	if err != nil {
+		return 0, err
+	}
+	if b := p.peek(); b != 0 {
+		return 0, &ErrUnsupportedExpr{rune(b)}
+	}
+	return v, nil
+}
+
+type memExprParser struct {
+	src string
+	pos int
+}
+
+func (p *memExprParser) skipSpace() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// peek returns the next non-space byte without consuming it, or 0 at
+// end of input.
+func (p *memExprParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *memExprParser) parseExpr() (uint32, error) {
+	v, err := p.parseTerm()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch p.peek() {
+		case '+':
+			p.pos++
+			rhs, err := p.parseTerm()
+			if err != nil {
+				return 0, err
+			}
+			sum := uint64(v) + uint64(rhs)
+			if sum > math.MaxUint32 {
+				return 0, &ErrUnsupportedExpr{"memory expression overflows uint32"}
+			}
+			v = uint32(sum)
+		case '-':
+			p.pos++
+			rhs, err := p.parseTerm()
+			if err != nil {
+				return 0, err
+			}
+			if rhs > v {
+				return 0, &ErrUnsupportedExpr{"memory expression has a negative intermediate result"}
+			}
+			v -= rhs
+		default:
+			return v, nil
+		}
+	}
+}
+
+func (p *memExprParser) parseTerm() (uint32, error) {
+	v, err := p.parseFactor()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch p.peek() {
+		case '*':
+			p.pos++
+			rhs, err := p.parseFactor()
+			if err != nil {
+				return 0, err
+			}
+			product := uint64(v) * uint64(rhs)
+			if product > math.MaxUint32 {
+				return 0, &ErrUnsupportedExpr{"memory expression overflows uint32"}
+			}
+			v = uint32(product)
+		case '/':
+			p.pos++
+			rhs, err := p.parseFactor()
+			if err != nil {
+				return 0, err
+			}
+			if rhs == 0 {
+				return 0, &ErrUnsupportedExpr{"division by zero in memory expression"}
+			}
+			v /= rhs
+		default:
+			return v, nil
+		}
+	}
+}
+
+func (p *memExprParser) parseFactor() (uint32, error) {
+	switch p.peek() {
+	case '(':
+		p.pos++
+		v, err := p.parseExpr()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, &ErrUnsupportedExpr{"unclosed ( in memory expression"}
+		}
+		p.pos++
+		return v, nil
+	case '-':
+		return 0, &ErrUnsupportedExpr{"unary - in memory expression"}
+	}
+	return p.parseNumber()
+}
+
+func (p *memExprParser) parseNumber() (uint32, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	if p.pos == start {
+		if p.pos < len(p.src) {
+			return 0, &ErrUnsupportedExpr{rune(p.src[p.pos])}
+		}
+		return 0, &ErrUnsupportedExpr{"expected a number in memory expression"}
+	}
+
+	n, err := strconv.ParseUint(p.src[start:p.pos], 10, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	unitStart := p.pos
+	for p.pos < len(p.src) && isUnitByte(p.src[p.pos]) {
+		p.pos++
+	}
+	unit := p.src[unitStart:p.pos]
+	if unit == "" {
+		return uint32(n), nil
+	}
+
+	mult, ok := memUnits[unit]
+	if !ok {
+		return 0, &ErrUnsupportedExpr{unit}
+	}
+	product := n * mult
+	if product > math.MaxUint32 {
+		return 0, &ErrUnsupportedExpr{"memory expression overflows uint32"}
+	}
+	return uint32(product), nil
+}
+
+func isUnitByte(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
@@ -0,0 +1,140 @@
+package argon2
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Params is the decoded form of a PHC-formatted argon2 hash string:
+//
+//	$<form>$v=<version>$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>
+//
+// This is the format produced by this package (and by other argon2
+// libraries, such as phc-crypto and hlandau's passlib), so Params lets
+// integrators inspect a stored hash's parameters without running a
+// verification.
+type Params struct {
+	Form        Form
+	Version     int
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	Salt        []byte
+	Key         []byte
+}
+
+// Encode renders p as a PHC-formatted hash string. Salt and Key are
+// always emitted with RawStdEncoding, regardless of how they were
+// decoded.
+func Encode(p Params) string {
+	var form string
+	switch p.Form {
+	case FormI:
+		form = "argon2i"
+	case FormID:
+		form = "argon2id"
+	case FormD:
+		form = "argon2d"
+	}
+	return fmt.Sprintf(
+		"$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		form,
+		p.Version,
+		p.Memory,
+		p.Iterations,
+		p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(p.Salt),
+		base64.RawStdEncoding.EncodeToString(p.Key),
+	)
+}
+
+// Decode parses a PHC-formatted argon2 hash string into Params. Per
+// the PHC string format's tolerance for base64url, salts encoded with
+// RawURLEncoding are accepted in addition to the RawStdEncoding this
+// package emits.
+//
+// Errors:
+//  *ErrInvalidHash
+//  *ErrInvalidForm
+//  *ErrIncompatibleVersion
+//  base64.CorruptInputError
+func Decode(hash string) (Params, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return Params{}, &ErrInvalidHash{}
+	}
+
+	var form Form
+	switch parts[1] {
+	case "argon2i":
+		form = FormI
+	case "argon2id":
+		form = FormID
+	case "argon2d":
+		form = FormD
+	default:
+		return Params{}, &ErrInvalidForm{}
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return Params{}, err
+	}
+	if version != argon2.Version {
+		return Params{}, &ErrIncompatibleVersion{version}
+	}
+
+	var memory, iterations uint32
+	var parallelism uint8
+	_, err := fmt.Sscanf(
+		parts[3],
+		"m=%d,t=%d,p=%d",
+		&memory,
+		&iterations,
+		&parallelism,
+	)
+	if err != nil {
+		return Params{}, err
+	}
+
+	salt, err := decodePHCBytes(parts[4])
+	if err != nil {
+		return Params{}, err
+	}
+	key, err := decodePHCBytes(parts[5])
+	if err != nil {
+		return Params{}, err
+	}
+
+	return Params{
+		Form:        form,
+		Version:     version,
+		Memory:      memory,
+		Iterations:  iterations,
+		Parallelism: parallelism,
+		Salt:        salt,
+		Key:         key,
+	}, nil
+}
+
+// MustDecode is like Decode but panics instead of returning an error.
+// It is intended for hashes already known to be well-formed, e.g. in
+// tests.
+func MustDecode(hash string) Params {
+	p, err := Decode(hash)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func decodePHCBytes(s string) ([]byte, error) {
+	b, err := base64.RawStdEncoding.DecodeString(s)
+	if err == nil {
+		return b, nil
+	}
+	return base64.RawURLEncoding.DecodeString(s)
+}
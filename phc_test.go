@@ -0,0 +1,93 @@
+package argon2
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	p := Params{
+		Form:        FormID,
+		Version:     19,
+		Memory:      65536,
+		Iterations:  3,
+		Parallelism: 2,
+		Salt:        []byte("0123456789012345"),
+		Key:         []byte("01234567890123456789012345678901"),
+	}
+
+	decoded, err := Decode(Encode(p))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !reflect.DeepEqual(p, decoded) {
+		t.Errorf("RoundTrip: %+v != %+v", decoded, p)
+	}
+}
+
+func TestDecodeReencodeRoundTrip(t *testing.T) {
+	hash, err := RecommendedHasher().Create(password)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	p, err := Decode(hash)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	reencoded := Encode(p)
+	if _, err := Decode(reencoded); err != nil {
+		t.Errorf("Decode_Reencoded: %v", err)
+	}
+}
+
+// TestDecodeURLSafeSalt proves the RawURLEncoding fallback in
+// decodePHCBytes is actually reachable: it hand-builds a PHC string
+// whose salt/key contain a byte sequence that base64-encodes with a
+// `/` in RawStdEncoding but a `_` in RawURLEncoding, so
+// RawStdEncoding.DecodeString rejects it and Decode must fall back.
+func TestDecodeURLSafeSalt(t *testing.T) {
+	raw := []byte{0x00, 0x3e, 0x3f, 0xc8}
+
+	stdEncoded := base64.RawStdEncoding.EncodeToString(raw)
+	urlEncoded := base64.RawURLEncoding.EncodeToString(raw)
+	if stdEncoded == urlEncoded {
+		t.Fatalf("test vector does not actually differ under std/url encoding")
+	}
+	if _, err := base64.RawStdEncoding.DecodeString(urlEncoded); err == nil {
+		t.Fatalf("test vector is valid RawStdEncoding; fallback would not be exercised")
+	}
+
+	hash := fmt.Sprintf("$argon2id$v=19$m=65536,t=3,p=2$%s$%s", urlEncoded, urlEncoded)
+
+	p, err := Decode(hash)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !bytes.Equal(p.Salt, raw) {
+		t.Errorf("Decode_URLSalt: got %x, want %x", p.Salt, raw)
+	}
+	if !bytes.Equal(p.Key, raw) {
+		t.Errorf("Decode_URLKey: got %x, want %x", p.Key, raw)
+	}
+}
+
+func TestDecodeInvalidForm(t *testing.T) {
+	_, err := Decode(hashInvalidForm)
+	if _, ok := err.(*ErrInvalidForm); !ok {
+		t.Errorf("Decode_InvalidForm: %T", err)
+	}
+}
+
+func TestMustDecodePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustDecode: expected a panic")
+		}
+	}()
+	MustDecode(hashInvalidForm)
+}
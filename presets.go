@@ -0,0 +1,81 @@
+package argon2
+
+// DefaultHasher returns the Hasher this package recommends when a
+// caller has no specific requirement of their own. It is currently an
+// alias for RecommendedHasher.
+func DefaultHasher() *Hasher {
+	return RecommendedHasher()
+}
+
+// RecommendedHasher returns a Hasher configured per the RFC 9106 /
+// OWASP "recommended" option: argon2id with m=64MiB, t=3, p=4, a
+// 16-byte salt, and a 32-byte key. Use this unless you have measured
+// that your hardware can afford SensitiveHasher, or that it can only
+// afford InteractiveHasher. It returns a fresh *Hasher on every call,
+// so callers can use the result directly (e.g.
+// RecommendedHasher().Create(password)) without risking mutation of
+// a shared package-level value.
+func RecommendedHasher() *Hasher {
+	return &Hasher{
+		Form:        FormID,
+		SaltLength:  16,
+		KeyLength:   32,
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 4,
+	}
+}
+
+// InteractiveHasher returns a Hasher configured per the RFC 9106 /
+// OWASP "second recommended" option, intended for interactive logins
+// where latency matters more than it does for RecommendedHasher:
+// argon2id with m=19MiB, t=2, p=1.
+func InteractiveHasher() *Hasher {
+	return &Hasher{
+		Form:        FormID,
+		SaltLength:  16,
+		KeyLength:   32,
+		Memory:      19 * 1024,
+		Iterations:  2,
+		Parallelism: 1,
+	}
+}
+
+// SensitiveHasher returns a Hasher configured for high-value secrets
+// where the extra cost of hashing is acceptable: argon2id with
+// m=1GiB, t=4, p=4.
+func SensitiveHasher() *Hasher {
+	return &Hasher{
+		Form:        FormID,
+		SaltLength:  16,
+		KeyLength:   32,
+		Memory:      1024 * 1024,
+		Iterations:  4,
+		Parallelism: 4,
+	}
+}
+
+// Validate reports whether h's parameters meet the floor set by
+// RecommendedHasher: argon2id, salt and key of at least 16 and 32
+// bytes, memory of at least 64MiB, at least 3 iterations, and at
+// least 4 degrees of parallelism. It returns a *ErrWeakHasherConfiguration
+// describing the first parameter found below that floor, or nil if h
+// meets or exceeds it.
+func (h *Hasher) Validate() error {
+	floor := RecommendedHasher()
+	switch {
+	case h.Form != FormID:
+		return &ErrWeakHasherConfiguration{"Form", "must be argon2id"}
+	case h.SaltLength < floor.SaltLength:
+		return &ErrWeakHasherConfiguration{"SaltLength", "below recommended floor"}
+	case h.KeyLength < floor.KeyLength:
+		return &ErrWeakHasherConfiguration{"KeyLength", "below recommended floor"}
+	case h.Memory < floor.Memory:
+		return &ErrWeakHasherConfiguration{"Memory", "below recommended floor"}
+	case h.Iterations < floor.Iterations:
+		return &ErrWeakHasherConfiguration{"Iterations", "below recommended floor"}
+	case h.Parallelism < floor.Parallelism:
+		return &ErrWeakHasherConfiguration{"Parallelism", "below recommended floor"}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package argon2
+
+import "testing"
+
+func TestRecommendedHasherCreatesWorkingHash(t *testing.T) {
+	createComparePasswords(t, RecommendedHasher())
+}
+
+func TestDefaultHasherIsRecommended(t *testing.T) {
+	d := DefaultHasher()
+	r := RecommendedHasher()
+	if *d != *r {
+		t.Errorf("DefaultHasher_NotRecommended: %+v != %+v", d, r)
+	}
+}
+
+func TestRecommendedHasherReturnsIndependentCopies(t *testing.T) {
+	a := RecommendedHasher()
+	b := RecommendedHasher()
+
+	a.Iterations = 1
+	if b.Iterations == a.Iterations {
+		t.Errorf("RecommendedHasher_SharedState: mutating one result affected another")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := RecommendedHasher().Validate(); err != nil {
+		t.Errorf("Validate_Recommended: %v", err)
+	}
+	if err := SensitiveHasher().Validate(); err != nil {
+		t.Errorf("Validate_Sensitive: %v", err)
+	}
+
+	weak := InteractiveHasher()
+	err := weak.Validate()
+	if err == nil {
+		t.Errorf("Validate_Interactive_NoErr")
+	}
+	if _, ok := err.(*ErrWeakHasherConfiguration); !ok {
+		t.Errorf("Validate_Interactive_WrongErr: %T", err)
+	}
+}